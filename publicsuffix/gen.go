@@ -0,0 +1,204 @@
+//go:build ignore
+
+// Command gen downloads the Public Suffix List and compiles it into
+// table.go: a flat trie stored as parallel slices (node labels as Go
+// strings, sorted child ID ranges, and indexes into a table of Rule
+// values that carry each rule's type and section) that List.Find can
+// walk without parsing a source file at runtime. The table is not
+// byte-packed. Run it with:
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+)
+
+const sourceURL = "https://publicsuffix.org/list/public_suffix_list.dat"
+
+func main() {
+	out := flag.String("out", "table.go", "output file")
+	flag.Parse()
+
+	src, lastModified, err := download(sourceURL)
+	if err != nil {
+		log.Fatalf("gen: %v", err)
+	}
+
+	l := publicsuffix.NewList()
+	rules, err := l.LoadString(src, publicsuffix.DefaultParserOptions)
+	if err != nil {
+		log.Fatalf("gen: parsing %s: %v", sourceURL, err)
+	}
+
+	c := compile(rules)
+	code, err := render(c, lastModified)
+	if err != nil {
+		log.Fatalf("gen: %v", err)
+	}
+
+	if err := os.WriteFile(*out, code, 0644); err != nil {
+		log.Fatalf("gen: writing %s: %v", *out, err)
+	}
+}
+
+func download(url string) (src, lastModified string, err error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("unexpected status %s fetching %s", resp.Status, url)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	return string(body), resp.Header.Get("Last-Modified"), nil
+}
+
+// node is the in-memory trie built from the parsed rules before it is
+// flattened into the table.go arrays.
+type node struct {
+	label    string
+	children []*node
+	rule     *publicsuffix.Rule // set if a normal/exception rule terminates here
+	wildcard *publicsuffix.Rule // set if this node has a "*" child rule
+}
+
+func (n *node) child(label string) *node {
+	for _, c := range n.children {
+		if c.label == label {
+			return c
+		}
+	}
+	c := &node{label: label}
+	n.children = append(n.children, c)
+	sort.Slice(n.children, func(i, j int) bool { return n.children[i].label < n.children[j].label })
+	return c
+}
+
+func compile(rules []publicsuffix.Rule) *node {
+	root := &node{}
+	for i := range rules {
+		r := &rules[i]
+		var labels []string
+		if r.Value != "" {
+			labels = publicsuffix.Labels(r.Value)
+		}
+		n := root
+		for i := len(labels) - 1; i >= 0; i-- {
+			n = n.child(labels[i])
+		}
+		if r.Type == publicsuffix.WildcardType {
+			n.wildcard = r
+		} else {
+			n.rule = r
+		}
+	}
+	return root
+}
+
+// flattened mirrors the parallel slices emitted into table.go.
+type flattened struct {
+	labels       []string
+	children     [][2]int
+	rules        []publicsuffix.Rule
+	terminal     []int
+	wildcardRule []int
+}
+
+func render(root *node, lastModified string) ([]byte, error) {
+	f := &flattened{}
+
+	// Nodes are emitted in level order (breadth-first), not pre-order, so
+	// that a node's children occupy a contiguous range of IDs containing
+	// only its direct children -- never grandchildren. newCompiledList and
+	// trieNode.find rely on that to slice and binary search the flattened
+	// table the same way the in-memory trie does.
+	order := []*node{root}
+	for id := 0; id < len(order); id++ {
+		n := order[id]
+		f.labels = append(f.labels, n.label)
+		f.children = append(f.children, [2]int{0, 0})
+		f.terminal = append(f.terminal, -1)
+		f.wildcardRule = append(f.wildcardRule, -1)
+
+		if n.rule != nil {
+			f.terminal[id] = len(f.rules)
+			f.rules = append(f.rules, *n.rule)
+		}
+		if n.wildcard != nil {
+			f.wildcardRule[id] = len(f.rules)
+			f.rules = append(f.rules, *n.wildcard)
+		}
+
+		start := len(order)
+		order = append(order, n.children...)
+		f.children[id] = [2]int{start, len(order)}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package publicsuffix\n\n")
+	fmt.Fprintf(&b, "// Code generated by gen.go via go:generate; DO NOT EDIT.\n")
+	fmt.Fprintf(&b, "// Source: %s\n\n", sourceURL)
+	fmt.Fprintf(&b, "const defaultListVersion = %q\n\n", version(lastModified))
+
+	fmt.Fprintf(&b, "var compiledLabel = []string{\n")
+	for _, l := range f.labels {
+		fmt.Fprintf(&b, "\t%q,\n", l)
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "var compiledChildren = [][2]uint32{\n")
+	for _, r := range f.children {
+		fmt.Fprintf(&b, "\t{%d, %d},\n", r[0], r[1])
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "var compiledTerminal = []int32{\n")
+	for _, t := range f.terminal {
+		fmt.Fprintf(&b, "\t%d,\n", t)
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "var compiledWildcardRule = []int32{\n")
+	for _, w := range f.wildcardRule {
+		fmt.Fprintf(&b, "\t%d,\n", w)
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "var compiledRules = []Rule{\n")
+	for _, r := range f.rules {
+		fmt.Fprintf(&b, "\t{Type: %d, Value: %q, Length: %d, Private: %v, Section: %d},\n",
+			r.Type, r.Value, r.Length, r.Private, r.Section)
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	return format.Source(b.Bytes())
+}
+
+func version(lastModified string) string {
+	if lastModified == "" {
+		return time.Now().UTC().Format("2006-01-02")
+	}
+	t, err := time.Parse(http.TimeFormat, lastModified)
+	if err != nil {
+		return strings.TrimSpace(lastModified)
+	}
+	return t.UTC().Format("2006-01-02")
+}
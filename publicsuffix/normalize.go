@@ -0,0 +1,105 @@
+package publicsuffix
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// fullWidthDot is the full-width variant of "." (U+FF0E), which browsers
+// and resolvers treat as an ordinary label separator.
+const fullWidthDot = "．"
+
+// idnaProfile canonicalizes both Unicode (U-label) and Punycode (A-label)
+// domain names to the same ASCII form, so rule matching never has to care
+// which one it was given. Unlike idna.Lookup, it does not enforce STD3
+// ASCII rules, so labels with underscores (e.g. "_dmarc", "_tcp" SRV
+// records) still normalize instead of being rejected -- callers doing
+// public suffix lookups need those to keep working, not just
+// browser-typed hostnames.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.StrictDomainName(false), idna.Transitional(false))
+
+// InvalidNameError reports that name could not be parsed as a domain name
+// at all, as opposed to being a valid name that happens to be a public
+// suffix itself (see SuffixError).
+type InvalidNameError struct {
+	Name string
+	Err  error
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("publicsuffix: %q is not a valid domain name: %v", e.Name, e.Err)
+}
+
+func (e *InvalidNameError) Unwrap() error {
+	return e.Err
+}
+
+// SuffixError reports that name is itself a public suffix (e.g. "com" or
+// "co.uk"), and therefore cannot be decomposed into a registrable domain.
+type SuffixError struct {
+	Name string
+}
+
+func (e *SuffixError) Error() string {
+	return fmt.Sprintf("publicsuffix: %q is a suffix", e.Name)
+}
+
+// normalize lowercases and IDNA-canonicalizes name, so that Unicode
+// (U-label) input such as "bücher.example.com" and Punycode (A-label)
+// input such as "xn--bcher-kva.example.com" compare equal to each other
+// and to the (also canonicalized) rule values stored in a List.
+func normalize(name string) (string, error) {
+	if name == "" {
+		return "", &InvalidNameError{Name: name, Err: fmt.Errorf("name is blank")}
+	}
+
+	name = strings.Replace(name, fullWidthDot, ".", -1)
+	name = strings.TrimSuffix(name, ".")
+
+	if strings.HasPrefix(name, ".") {
+		return "", &InvalidNameError{Name: name, Err: fmt.Errorf("name starts with a dot")}
+	}
+
+	ascii, err := idnaProfile.ToASCII(strings.ToLower(name))
+	if err != nil {
+		return "", &InvalidNameError{Name: name, Err: err}
+	}
+	return ascii, nil
+}
+
+// canonicalizeRuleLine rewrites a Public Suffix List rule line into the
+// same canonical (lowercased, IDNA ASCII) form normalize produces for
+// input names, preserving the "*." and "!" prefixes that give the rule
+// its type. Lines that cannot be IDNA-encoded are returned unchanged, so
+// a handful of unparseable entries in the source don't abort loading the
+// rest of the list.
+func canonicalizeRuleLine(line string) string {
+	prefix, value := "", line
+	switch {
+	case strings.HasPrefix(line, "*."):
+		prefix, value = "*.", line[2:]
+	case strings.HasPrefix(line, "!"):
+		prefix, value = "!", line[1:]
+	}
+
+	ascii, err := idnaProfile.ToASCII(strings.ToLower(value))
+	if err != nil {
+		return line
+	}
+	return prefix + ascii
+}
+
+// toOutputForm converts name -- already in canonical ASCII form -- back to
+// Unicode when options asks for it.
+func toOutputForm(name string, options *FindOptions) string {
+	if options == nil || options.ASCIIOutput || name == "" {
+		return name
+	}
+	unicode, err := idnaProfile.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+	return unicode
+}
@@ -0,0 +1,71 @@
+package publicsuffix
+
+import "testing"
+
+func TestDomain_CaseTrailingDotAndFullWidthDot(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "mixed case", in: "WWW.Example.COM"},
+		{name: "trailing dot", in: "www.example.com."},
+		{name: "full-width dot", in: "www．example．com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Domain(tt.in)
+			if err != nil {
+				t.Fatalf("Domain(%q) error = %v", tt.in, err)
+			}
+			if want := "example.com"; got != want {
+				t.Errorf("Domain(%q) = %q, want %q", tt.in, got, want)
+			}
+		})
+	}
+}
+
+// TestDomain_UnderscoreLabel confirms that labels with underscores, such
+// as those used by DMARC, DKIM and SRV records, still normalize instead
+// of being rejected by STD3 ASCII rules.
+func TestDomain_UnderscoreLabel(t *testing.T) {
+	got, err := Domain("_dmarc.example.com")
+	if err != nil {
+		t.Fatalf("Domain() error = %v", err)
+	}
+	if want := "example.com"; got != want {
+		t.Errorf("Domain() = %q, want %q", got, want)
+	}
+}
+
+func TestPublicSuffix_IDNAWildcard(t *testing.T) {
+	l, err := NewListFromString("jp\n*.東京.jp\n", DefaultParserOptions)
+	if err != nil {
+		t.Fatalf("NewListFromString() error = %v", err)
+	}
+
+	const name = "a.shibuya.東京.jp"
+
+	asciiSuffix, icann, err := PublicSuffixFromListWithOptions(l, name, &FindOptions{ASCIIOutput: true})
+	if err != nil {
+		t.Fatalf("PublicSuffixFromListWithOptions(%q) error = %v", name, err)
+	}
+	if !icann {
+		t.Errorf("PublicSuffixFromListWithOptions(%q) icann = false, want true", name)
+	}
+	wantASCII, err := idnaProfile.ToASCII("shibuya.東京.jp")
+	if err != nil {
+		t.Fatalf("idnaProfile.ToASCII() error = %v", err)
+	}
+	if asciiSuffix != wantASCII {
+		t.Errorf("PublicSuffixFromListWithOptions(%q) suffix = %q, want %q", name, asciiSuffix, wantASCII)
+	}
+
+	unicodeSuffix, _, err := PublicSuffixFromListWithOptions(l, name, &FindOptions{ASCIIOutput: false})
+	if err != nil {
+		t.Fatalf("PublicSuffixFromListWithOptions(%q) error = %v", name, err)
+	}
+	if want := "shibuya.東京.jp"; unicodeSuffix != want {
+		t.Errorf("PublicSuffixFromListWithOptions(%q) suffix = %q, want %q", name, unicodeSuffix, want)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 const (
@@ -18,12 +19,31 @@ const (
 	WildcardType  = 2
 	ExceptionType = 3
 
+	// ICANNSection and PrivateSection identify which section of a Public
+	// Suffix List source a Rule was read from. A Rule built with NewRule
+	// directly, rather than through a parsed list, has a zero Section.
+	ICANNSection   = 1
+	PrivateSection = 2
+
 	listTokenPrivateDomains = "===BEGIN PRIVATE DOMAINS==="
 	listTokenComment        = "//"
 )
 
+// Finder is implemented by anything that can look up the Rule matching a
+// domain name, such as a *List (parsed, or the compiled-in DefaultList) or
+// a RefreshingList. CookieJarList and StrictCookieJarList look rules up
+// through a Finder; Domain, Parse and the *FromListWithOptions functions
+// take a *List and do not accept other Finder implementations.
+type Finder interface {
+	Find(name string, options *FindOptions) Rule
+}
+
 // defaultList is the default List and it is used by Parse and Domain.
-var DefaultList = NewList()
+// It is backed by the table compiled into table.go by gen.go, so it is
+// ready to use without loading or parsing a Public Suffix List source.
+var DefaultList = newCompiledList()
+
+//go:generate go run gen.go -out table.go
 
 // DefaultRule is the default Rule that represents "*".
 var DefaultRule = NewRule("*")
@@ -32,7 +52,7 @@ var DefaultRule = NewRule("*")
 var DefaultParserOptions = &ParserOption{PrivateDomains: true}
 
 // DefaultFindOptions are the default options used to perform the lookup of rules in the list.
-var DefaultFindOptions = &FindOptions{IgnorePrivate: false}
+var DefaultFindOptions = &FindOptions{IgnorePrivate: false, ASCIIOutput: true}
 
 // Rule represents a single rule in a Public Suffix List.
 type Rule struct {
@@ -40,6 +60,9 @@ type Rule struct {
 	Value   string
 	Length  int
 	Private bool
+	// Section is ICANNSection or PrivateSection, depending on which part
+	// of the source list the rule was read from.
+	Section int
 }
 
 // ParserOption are the options you can use to customize the way a List
@@ -52,6 +75,10 @@ type ParserOption struct {
 // is searched within the list.
 type FindOptions struct {
 	IgnorePrivate bool
+	// ASCIIOutput controls the encoding of the TLD/SLD/TRD returned by
+	// Parse and the *FromListWithOptions functions: true (the default)
+	// returns the Punycode (A-label) form, false returns Unicode.
+	ASCIIOutput bool
 }
 
 // List represents a Public Suffix List.
@@ -59,6 +86,16 @@ type List struct {
 	// rules is kept private because you should not access rules directly
 	// for lookup optimization the list will not be guaranteed to be a simple slice forever
 	rules []Rule
+
+	// trie indexes rules for Find. It is built lazily from rules on the
+	// first call to Find, and invalidated by AddRule. trieMu guards both
+	// so concurrent Find calls on a List that is still being populated
+	// (or that hasn't been looked up yet) never race on trie. It's an
+	// RWMutex rather than a plain Mutex so the common case -- trie
+	// already built -- only takes a read lock and concurrent Find calls
+	// don't serialize on each other.
+	trieMu sync.RWMutex
+	trie   *trieNode
 }
 
 // NewList creates a new empty list.
@@ -109,7 +146,10 @@ func (l *List) LoadFile(path string, options *ParserOption) ([]Rule, error) {
 // The list may be optimized internally for lookups, therefore the algorithm
 // will decide the best position for the new rule.
 func (l *List) AddRule(r *Rule) error {
+	l.trieMu.Lock()
 	l.rules = append(l.rules, *r)
+	l.trie = nil
+	l.trieMu.Unlock()
 	return nil
 }
 
@@ -148,8 +188,13 @@ Scanning:
 			break
 
 		default:
-			rule := NewRule(line)
+			rule := NewRule(canonicalizeRuleLine(line))
 			rule.Private = (section == 2)
+			if section == 2 {
+				rule.Section = PrivateSection
+			} else {
+				rule.Section = ICANNSection
+			}
 			l.AddRule(rule)
 			rules = append(rules, *rule)
 		}
@@ -160,44 +205,55 @@ Scanning:
 }
 
 // Find and returns the most appropriate rule for the domain name.
+//
+// The lookup walks the name's labels right-to-left (TLD inward) against a
+// trie built from the list's rules, binary searching each node's children
+// for the next label. This makes Find O(labels · log fanout) instead of a
+// linear scan over every rule, which matters once a list holds the full
+// Public Suffix List (about 9,500 rules in table.go).
 func (l *List) Find(name string, options *FindOptions) Rule {
-	var rule *Rule
+	if options == nil {
+		options = DefaultFindOptions
+	}
+
+	node := l.ensureTrie()
+	labels := Labels(name)
 
-	for _, r := range l.selectRules(name, options) {
+	var best *Rule
+	consider := func(r *Rule) bool {
+		if options.IgnorePrivate && r.Private {
+			return false
+		}
 		if r.Type == ExceptionType {
-			return r
+			best = r
+			return true
 		}
-		if rule == nil || rule.Length < r.Length {
-			rule = &r
+		if best == nil || best.Length < r.Length {
+			best = r
 		}
+		return false
 	}
 
-	if rule != nil {
-		return *rule
-	}
-
-	return *DefaultRule
-}
-
-func (l *List) selectRules(name string, options *FindOptions) []Rule {
-	var found []Rule
+	for i := len(labels) - 1; i >= 0 && node != nil; i-- {
+		// A wildcard child matches any label, so it is checked before
+		// descending into the (possibly absent) literal child.
+		if node.wildcard != nil && consider(node.wildcard.rule) {
+			return *best
+		}
 
-	if options == nil {
-		options = DefaultFindOptions
+		node = node.find(labels[i])
+		if node != nil && node.rule != nil {
+			if consider(node.rule) {
+				return *best
+			}
+		}
 	}
 
-	// In this phase the search is a simple sequential scan
-	for _, rule := range l.rules {
-		if !rule.Match(name) {
-			continue
-		}
-		if options.IgnorePrivate && rule.Private {
-			continue
-		}
-		found = append(found, rule)
+	if best != nil {
+		return *best
 	}
 
-	return found
+	return *DefaultRule
 }
 
 // NewRule parses the rule content, creates and returns a Rule.
@@ -355,6 +411,58 @@ func Parse(name string) (*DomainName, error) {
 	return ParseFromListWithOptions(DefaultList, name, DefaultFindOptions)
 }
 
+// PublicSuffix extract and returns the public suffix of the input,
+// together with a boolean telling whether the suffix is managed by
+// the ICANN section (true) or the PRIVATE section (false) of the
+// default (Public Suffix) List.
+//
+// Examples:
+//
+// 	publicsuffix.PublicSuffix("example.com")
+//	// com, true
+// 	publicsuffix.PublicSuffix("foo.blogspot.co.uk")
+//	// blogspot.co.uk, false
+//
+func PublicSuffix(name string) (suffix string, icann bool, err error) {
+	return PublicSuffixFromListWithOptions(DefaultList, name, DefaultFindOptions)
+}
+
+// PublicSuffixFromListWithOptions extract and returns the public suffix
+// of the input, together with a boolean telling whether the suffix is
+// managed by the ICANN section (true) or the PRIVATE section (false) of
+// the (Public Suffix) list passed as argument.
+func PublicSuffixFromListWithOptions(l *List, name string, options *FindOptions) (suffix string, icann bool, err error) {
+	n, err := normalize(name)
+	if err != nil {
+		return "", false, err
+	}
+
+	r := l.Find(n, options)
+	suffix = toOutputForm(r.Decompose(n)[1], options)
+	return suffix, r.Section == ICANNSection, nil
+}
+
+// SplitDomain decomposes name into its eTLD+1 (etldPlus1) and its public
+// suffix (suffix), using the default (Public Suffix) List, and reports
+// whether suffix came from the ICANN section. Callers implementing cookie
+// policy need this distinction: browsers only allow cookies to be scoped
+// to an ICANN suffix's eTLD+1, never to a PRIVATE one (e.g. a cookie
+// scoped to "blogspot.co.uk" would let any blogspot.co.uk tenant read
+// cookies meant for another).
+func SplitDomain(name string) (etldPlus1, suffix string, icann bool, rule *Rule, err error) {
+	return SplitDomainFromListWithOptions(DefaultList, name, DefaultFindOptions)
+}
+
+// SplitDomainFromListWithOptions is like SplitDomain but against the
+// (Public Suffix) list passed as argument.
+func SplitDomainFromListWithOptions(l *List, name string, options *FindOptions) (etldPlus1, suffix string, icann bool, rule *Rule, err error) {
+	dn, err := ParseFromListWithOptions(l, name, options)
+	if err != nil {
+		return "", "", false, nil, err
+	}
+	return dn.SLD + "." + dn.TLD, dn.TLD, dn.Rule.Section == ICANNSection, dn.Rule, nil
+}
+
 // DomainFromListWithOptions extract and return the domain name from the input
 // using the (Public Suffix) list passed as argument.
 //
@@ -394,6 +502,10 @@ func DomainFromListWithOptions(l *List, name string, options *FindOptions) (stri
 //	// &DomainName{"co.uk", "example"}
 //
 func ParseFromListWithOptions(l *List, name string, options *FindOptions) (*DomainName, error) {
+	if options == nil {
+		options = DefaultFindOptions
+	}
+
 	n, err := normalize(name)
 	if err != nil {
 		return nil, err
@@ -401,27 +513,17 @@ func ParseFromListWithOptions(l *List, name string, options *FindOptions) (*Doma
 
 	r := l.Find(n, options)
 	if tld := r.Decompose(n)[1]; tld == "" {
-		return nil, fmt.Errorf("%s is a suffix", n)
+		return nil, &SuffixError{Name: n}
 	}
 
 	dn := &DomainName{Rule: &r}
 	dn.TLD, dn.SLD, dn.TRD = decompose(&r, n)
+	dn.TLD = toOutputForm(dn.TLD, options)
+	dn.SLD = toOutputForm(dn.SLD, options)
+	dn.TRD = toOutputForm(dn.TRD, options)
 	return dn, nil
 }
 
-func normalize(name string) (string, error) {
-	ret := strings.ToLower(name)
-
-	if ret == "" {
-		return "", fmt.Errorf("Name is blank")
-	}
-	if ret[0] == '.' {
-		return "", fmt.Errorf("Name %s starts with a dot", ret)
-	}
-
-	return ret, nil
-}
-
 func decompose(r *Rule, name string) (tld, sld, trd string) {
 	parts := r.Decompose(name)
 	left, tld := parts[0], parts[1]
@@ -438,16 +540,27 @@ func decompose(r *Rule, name string) (tld, sld, trd string) {
 	return
 }
 
-// CookieJarList implements the cookiejar.PublicSuffixList interface.
-var CookieJarList cookiejar.PublicSuffixList = cookiejarList{DefaultList}
+// CookieJarList implements the cookiejar.PublicSuffixList interface using
+// the default list, including PRIVATE section rules -- matching the
+// historical, permissive behavior of this package and of
+// golang.org/x/net/publicsuffix.
+var CookieJarList cookiejar.PublicSuffixList = cookiejarList{DefaultList, &FindOptions{IgnorePrivate: false}}
+
+// StrictCookieJarList implements cookiejar.PublicSuffixList using only
+// the ICANN section of the default list. Use this instead of
+// CookieJarList when the caller must enforce the rule that cookies may
+// only be scoped to an ICANN-registered suffix, never a PRIVATE one (see
+// SplitDomain).
+var StrictCookieJarList cookiejar.PublicSuffixList = cookiejarList{DefaultList, &FindOptions{IgnorePrivate: true}}
 
 type cookiejarList struct {
-	List *List
+	Finder  Finder
+	Options *FindOptions
 }
 
 // PublicSuffix implements cookiejar.PublicSuffixList.
 func (l cookiejarList) PublicSuffix(domain string) string {
-	rule := l.List.Find(domain, nil)
+	rule := l.Finder.Find(domain, l.Options)
 	return rule.Decompose(domain)[1]
 }
 
@@ -0,0 +1,129 @@
+package publicsuffix
+
+import "testing"
+
+// fixtureList is a small, hand-picked excerpt of the real Public Suffix
+// List covering the ICANN/PRIVATE interactions exercised below: a plain
+// ICANN rule shadowed by a PRIVATE wildcard (de / *.uberspace.de), a bare
+// wildcard with no non-wildcard sibling (*.bd), a wildcard with an
+// exception (*.ck / !www.ck), and PRIVATE rules that are more specific
+// than their ICANN counterparts (co.uk / blogspot.co.uk, org / dyndns.org).
+const fixtureList = `
+de
+uk
+co.uk
+org
+*.bd
+*.ck
+!www.ck
+
+===BEGIN PRIVATE DOMAINS===
+*.uberspace.de
+blogspot.co.uk
+dyndns.org
+`
+
+func newFixtureList(t *testing.T) *List {
+	t.Helper()
+	l, err := NewListFromString(fixtureList, DefaultParserOptions)
+	if err != nil {
+		t.Fatalf("NewListFromString() error = %v", err)
+	}
+	return l
+}
+
+func TestPublicSuffixFromListWithOptions(t *testing.T) {
+	l := newFixtureList(t)
+
+	tests := []struct {
+		name   string
+		suffix string
+		icann  bool
+	}{
+		// *.uberspace.de (PRIVATE) only applies once its own front label
+		// has matched; "uberspace.de" alone falls back to the shorter,
+		// ICANN "de" rule rather than reporting PRIVATE.
+		{name: "uberspace.de", suffix: "de", icann: true},
+		{name: "www.x.uberspace.de", suffix: "x.uberspace.de", icann: false},
+
+		// *.bd (ICANN) has no non-wildcard sibling, so "bd" alone isn't a
+		// suffix of anything -- a "no such TLD" situation, not PRIVATE.
+		{name: "bd", suffix: "", icann: false},
+		{name: "www.x.bd", suffix: "x.bd", icann: true},
+
+		// *.ck (ICANN) is shadowed by the !www.ck exception.
+		{name: "www.ck", suffix: "ck", icann: true},
+		{name: "www.x.ck", suffix: "x.ck", icann: true},
+
+		// blogspot.co.uk (PRIVATE) is more specific than co.uk (ICANN).
+		{name: "foo.co.uk", suffix: "co.uk", icann: true},
+		{name: "foo.blogspot.co.uk", suffix: "blogspot.co.uk", icann: false},
+
+		// dyndns.org (PRIVATE) is more specific than org (ICANN).
+		{name: "foo.org", suffix: "org", icann: true},
+		{name: "foo.dyndns.org", suffix: "dyndns.org", icann: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suffix, icann, err := PublicSuffixFromListWithOptions(l, tt.name, DefaultFindOptions)
+			if err != nil {
+				t.Fatalf("PublicSuffixFromListWithOptions(%q) error = %v", tt.name, err)
+			}
+			if suffix != tt.suffix {
+				t.Errorf("PublicSuffixFromListWithOptions(%q) suffix = %q, want %q", tt.name, suffix, tt.suffix)
+			}
+			if icann != tt.icann {
+				t.Errorf("PublicSuffixFromListWithOptions(%q) icann = %v, want %v", tt.name, icann, tt.icann)
+			}
+		})
+	}
+}
+
+// TestDomain_DefaultList exercises the package-level entry points against
+// the compiled-in table (see table.go), confirming they work without the
+// caller loading a Public Suffix List source first.
+func TestDomain_DefaultList(t *testing.T) {
+	got, err := Domain("www.example.co.uk")
+	if err != nil {
+		t.Fatalf("Domain() error = %v", err)
+	}
+	if want := "example.co.uk"; got != want {
+		t.Errorf("Domain() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitDomain(t *testing.T) {
+	l := newFixtureList(t)
+
+	etldPlus1, suffix, icann, rule, err := SplitDomainFromListWithOptions(l, "foo.blogspot.co.uk", DefaultFindOptions)
+	if err != nil {
+		t.Fatalf("SplitDomainFromListWithOptions() error = %v", err)
+	}
+	if etldPlus1 != "foo.blogspot.co.uk" {
+		t.Errorf("etldPlus1 = %q, want %q", etldPlus1, "foo.blogspot.co.uk")
+	}
+	if suffix != "blogspot.co.uk" {
+		t.Errorf("suffix = %q, want %q", suffix, "blogspot.co.uk")
+	}
+	if icann {
+		t.Errorf("icann = true, want false")
+	}
+	if rule == nil || rule.Value != "blogspot.co.uk" {
+		t.Errorf("rule = %+v, want Value = %q", rule, "blogspot.co.uk")
+	}
+}
+
+// TestCookieJarLists confirms that a private-suffix domain is rejected as
+// a cookie scope by StrictCookieJarList but accepted by the permissive
+// CookieJarList, using the compiled default table.
+func TestCookieJarLists(t *testing.T) {
+	const domain = "foo.blogspot.co.uk"
+
+	if got, want := CookieJarList.PublicSuffix(domain), "blogspot.co.uk"; got != want {
+		t.Errorf("CookieJarList.PublicSuffix(%q) = %q, want %q", domain, got, want)
+	}
+	if got, want := StrictCookieJarList.PublicSuffix(domain), "co.uk"; got != want {
+		t.Errorf("StrictCookieJarList.PublicSuffix(%q) = %q, want %q", domain, got, want)
+	}
+}
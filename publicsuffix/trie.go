@@ -0,0 +1,105 @@
+package publicsuffix
+
+import "sort"
+
+// trieNode is one node of the reverse-label lookup trie built from a List's
+// rules. Each node corresponds to a single label of one or more rule values,
+// read right-to-left (TLD first). Children are kept sorted by label so a
+// lookup can binary search for the next label instead of scanning linearly.
+type trieNode struct {
+	label    string
+	children []*trieNode
+
+	// wildcard, when non-nil, holds the rule that matches any single label
+	// at this depth (e.g. the "*" in "*.uberspace.de"). It is kept apart
+	// from children because it does not correspond to a literal label and
+	// must always be considered regardless of what the input label is.
+	wildcard *trieNode
+
+	// rule is set when a rule (normal or exception) terminates exactly at
+	// this node.
+	rule *Rule
+}
+
+// find returns the child labeled label, or nil if there is none. Children
+// are sorted, so this is a binary search rather than a linear scan.
+func (n *trieNode) find(label string) *trieNode {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].label >= label
+	})
+	if i < len(n.children) && n.children[i].label == label {
+		return n.children[i]
+	}
+	return nil
+}
+
+// child returns the child labeled label, creating it (in sorted position)
+// if it does not already exist.
+func (n *trieNode) child(label string) *trieNode {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].label >= label
+	})
+	if i < len(n.children) && n.children[i].label == label {
+		return n.children[i]
+	}
+	c := &trieNode{label: label}
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = c
+	return c
+}
+
+// buildTrie indexes every rule in l.rules into a fresh trie, keyed on the
+// rule values read right-to-left.
+func buildTrie(rules []Rule) *trieNode {
+	root := &trieNode{}
+	for i := range rules {
+		insertRule(root, &rules[i])
+	}
+	return root
+}
+
+func insertRule(root *trieNode, r *Rule) {
+	var labels []string
+	if r.Value != "" {
+		labels = Labels(r.Value)
+	}
+
+	node := root
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = node.child(labels[i])
+	}
+
+	if r.Type == WildcardType {
+		if node.wildcard == nil {
+			node.wildcard = &trieNode{}
+		}
+		node.wildcard.rule = r
+		return
+	}
+	node.rule = r
+}
+
+// ensureTrie builds l.trie from l.rules if it hasn't been built yet, or was
+// invalidated by a call to AddRule since the last build. It is safe for
+// concurrent use, so Find can call it lazily without racing with another
+// goroutine's first Find (or with a concurrent AddRule) on the same List.
+//
+// The common case -- a trie that's already built, e.g. the compiled
+// DefaultList, or any List whose first Find has already run -- only takes
+// a read lock, so concurrent Find calls don't serialize on each other.
+func (l *List) ensureTrie() *trieNode {
+	l.trieMu.RLock()
+	trie := l.trie
+	l.trieMu.RUnlock()
+	if trie != nil {
+		return trie
+	}
+
+	l.trieMu.Lock()
+	defer l.trieMu.Unlock()
+	if l.trie == nil {
+		l.trie = buildTrie(l.rules)
+	}
+	return l.trie
+}
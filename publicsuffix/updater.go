@@ -0,0 +1,292 @@
+package publicsuffix
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fetcher retrieves a Public Suffix List source over HTTP, using the
+// ETag/Last-Modified headers of a previous response to avoid downloading
+// the body again when it hasn't changed.
+type Fetcher struct {
+	// Client is used to make the request. It defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// NewFetcher returns a Fetcher that issues requests with client, or with
+// http.DefaultClient if client is nil.
+func NewFetcher(client *http.Client) *Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Fetcher{Client: client}
+}
+
+// FetchResult is the outcome of a single Fetch.
+type FetchResult struct {
+	// Body holds the response body. It is nil when NotModified is true.
+	Body []byte
+	// ETag and LastModified are the response's conditional-request
+	// headers, to be passed back into the next Fetch call.
+	ETag         string
+	LastModified string
+	// NotModified is true when the server reported, via a 304 response,
+	// that etag/lastModified are still current.
+	NotModified bool
+}
+
+// Fetch retrieves url, sending etag and lastModified (both may be empty)
+// as conditional-request headers.
+func (f *Fetcher) Fetch(ctx context.Context, url string, etag, lastModified string) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &FetchResult{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("publicsuffix: unexpected status %s fetching %s", resp.Status, url)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &FetchResult{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// cacheEntry is the on-disk sidecar for a cached list, stored alongside
+// the raw bytes. When Refresh falls back to the cache, the sidecar
+// restores the ETag/Last-Modified used for later conditional requests and
+// the Version() of the cached list. It is not read otherwise, so the first
+// fetch of a new RefreshingList is unconditional.
+type cacheEntry struct {
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+	Version      string `json:"version"`
+}
+
+func cacheMetaPath(path string) string { return path + ".meta.json" }
+
+func loadCache(path string) (body []byte, meta cacheEntry, err error) {
+	body, err = os.ReadFile(path)
+	if err != nil {
+		return nil, cacheEntry{}, err
+	}
+	metaBytes, err := os.ReadFile(cacheMetaPath(path))
+	if err != nil {
+		// A cached body without metadata is still usable; it just forces
+		// the next Refresh to do a full, unconditional fetch.
+		return body, cacheEntry{}, nil
+	}
+	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+		return body, cacheEntry{}, nil
+	}
+	return body, meta, nil
+}
+
+func saveCache(path string, body []byte, meta cacheEntry) error {
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		return err
+	}
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cacheMetaPath(path), metaBytes, 0644)
+}
+
+// RefreshingList is a Finder that periodically re-fetches a Public Suffix
+// List source over HTTP and atomically swaps it in, so long-running
+// services (mail servers, cookie jars) can stay current without being
+// redeployed. Reads (Find) never observe a partially loaded list.
+type RefreshingList struct {
+	// URL is the Public Suffix List source to fetch, typically
+	// "https://publicsuffix.org/list/public_suffix_list.dat".
+	URL string
+	// CachePath, if non-empty, is where the raw source and its
+	// conditional-request metadata are cached on disk after each
+	// successful fetch. The cache is read only as a fallback when a fetch
+	// fails; Refresh always tries the network first, so the cache does
+	// not avoid a cold start fetch.
+	CachePath string
+	// ParserOption configures how a fetched source is parsed. Defaults to
+	// DefaultParserOptions.
+	ParserOption *ParserOption
+	// Fetcher performs the HTTP requests. Defaults to NewFetcher(nil).
+	Fetcher *Fetcher
+	// Checksum, if non-nil, pins the expected SHA-256 of the fetched
+	// body; a Refresh that downloads a body with a different checksum
+	// fails without swapping in the new list.
+	Checksum []byte
+
+	mu      sync.RWMutex
+	list    *List
+	etag    string
+	lastMod string
+	version string
+}
+
+// Find implements Finder by delegating to the most recently loaded list.
+// Before the first successful Refresh, it behaves like an empty List.
+func (rl *RefreshingList) Find(name string, options *FindOptions) Rule {
+	rl.mu.RLock()
+	l := rl.list
+	rl.mu.RUnlock()
+
+	if l == nil {
+		return NewList().Find(name, options)
+	}
+	return l.Find(name, options)
+}
+
+// Version returns the version of the currently loaded list, read from the
+// "// Version:" comment in its source (or, for a list loaded from the
+// on-disk cache, the version saved with it). Response headers are not
+// used. It is empty before the first successful Refresh, and when the
+// source has no such comment.
+func (rl *RefreshingList) Version() string {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.version
+}
+
+// Refresh fetches the latest list and, if it has changed, parses and
+// atomically swaps it in. It reports updated = true only when a new list
+// was actually swapped in.
+//
+// If the fetch fails and CachePath is set, Refresh falls back to the
+// on-disk cache (useful on startup, before any successful fetch). If the
+// fetch fails and there is no usable cache, Refresh returns the error and
+// leaves the currently loaded list, if any, untouched.
+func (rl *RefreshingList) Refresh(ctx context.Context) (updated bool, err error) {
+	rl.mu.RLock()
+	etag, lastMod := rl.etag, rl.lastMod
+	rl.mu.RUnlock()
+
+	fetcher := rl.Fetcher
+	if fetcher == nil {
+		fetcher = NewFetcher(nil)
+	}
+
+	result, fetchErr := fetcher.Fetch(ctx, rl.URL, etag, lastMod)
+	if fetchErr != nil {
+		if rl.CachePath == "" {
+			return false, fetchErr
+		}
+		body, meta, cacheErr := loadCache(rl.CachePath)
+		if cacheErr != nil {
+			return false, fetchErr
+		}
+		// The cache already backs the loaded list (e.g. a previous tick
+		// fell back to it, or loaded it at startup): nothing changed, so
+		// don't reparse and re-swap it on every failed tick.
+		rl.mu.RLock()
+		alreadyLoaded := rl.list != nil && rl.etag == meta.ETag && rl.lastMod == meta.LastModified
+		rl.mu.RUnlock()
+		if alreadyLoaded {
+			return false, nil
+		}
+		return rl.load(body, meta.ETag, meta.LastModified, meta.Version)
+	}
+	if result.NotModified {
+		return false, nil
+	}
+
+	if len(rl.Checksum) > 0 {
+		sum := sha256.Sum256(result.Body)
+		if !bytes.Equal(sum[:], rl.Checksum) {
+			return false, fmt.Errorf("publicsuffix: checksum mismatch fetching %s", rl.URL)
+		}
+	}
+
+	version := parseVersionComment(result.Body)
+	if rl.CachePath != "" {
+		meta := cacheEntry{ETag: result.ETag, LastModified: result.LastModified, Version: version}
+		if err := saveCache(rl.CachePath, result.Body, meta); err != nil {
+			return false, err
+		}
+	}
+
+	return rl.load(result.Body, result.ETag, result.LastModified, version)
+}
+
+func (rl *RefreshingList) load(body []byte, etag, lastModified, version string) (bool, error) {
+	options := rl.ParserOption
+	if options == nil {
+		options = DefaultParserOptions
+	}
+
+	l, err := NewListFromString(string(body), options)
+	if err != nil {
+		return false, err
+	}
+	// Build the trie now, before l is published, so a Find racing the
+	// swap below always sees a list whose trie is already built instead
+	// of maybe being the one to build it.
+	l.ensureTrie()
+
+	rl.mu.Lock()
+	rl.list = l
+	rl.etag = etag
+	rl.lastMod = lastModified
+	rl.version = version
+	rl.mu.Unlock()
+
+	return true, nil
+}
+
+// Start begins a goroutine that calls Refresh every interval until ctx is
+// canceled. Refresh errors are not surfaced; callers that need to observe
+// them should call Refresh directly on their own schedule instead.
+func (rl *RefreshingList) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rl.Refresh(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func parseVersionComment(body []byte) string {
+	const marker = "// Version:"
+	for _, line := range strings.Split(string(body), "\n") {
+		if idx := strings.Index(line, marker); idx != -1 {
+			return strings.TrimSpace(line[idx+len(marker):])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package publicsuffix
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestRefreshingList_Refresh(t *testing.T) {
+	const src = "com\n// Version: 2024-03-01\n"
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == "v1" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", "v1")
+		w.Write([]byte(src))
+	}))
+	defer srv.Close()
+
+	rl := &RefreshingList{URL: srv.URL, CachePath: filepath.Join(t.TempDir(), "psl.dat")}
+
+	updated, err := rl.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if !updated {
+		t.Fatalf("Refresh() updated = false on first fetch, want true")
+	}
+	if got, want := rl.Version(), "2024-03-01"; got != want {
+		t.Errorf("Version() = %q, want %q", got, want)
+	}
+	if rule := rl.Find("example.com", nil); rule.Value != "com" {
+		t.Errorf("Find(%q).Value = %q, want %q", "example.com", rule.Value, "com")
+	}
+
+	// A second refresh should be a conditional request that the server
+	// answers with 304, so the list is not reparsed.
+	updated, err = rl.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if updated {
+		t.Errorf("Refresh() updated = true on unchanged source, want false")
+	}
+	if requests != 2 {
+		t.Errorf("server received %d requests, want 2", requests)
+	}
+}
+
+func TestRefreshingList_ChecksumMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("com\n"))
+	}))
+	defer srv.Close()
+
+	rl := &RefreshingList{URL: srv.URL, Checksum: []byte("not the real checksum")}
+	if _, err := rl.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh() error = nil, want checksum mismatch error")
+	}
+}
+
+func TestRefreshingList_FallsBackToCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "psl.dat")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("com\n"))
+	}))
+	rl := &RefreshingList{URL: srv.URL, CachePath: cachePath}
+	if _, err := rl.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	srv.Close() // the server is now unreachable
+
+	stale := &RefreshingList{URL: srv.URL, CachePath: cachePath}
+	updated, err := stale.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v, want fallback to cache", err)
+	}
+	if !updated {
+		t.Errorf("Refresh() updated = false loading from cache, want true")
+	}
+	if rule := stale.Find("example.com", nil); rule.Value != "com" {
+		t.Errorf("Find(%q).Value = %q, want %q", "example.com", rule.Value, "com")
+	}
+
+	// A later tick, still offline, falls back to the same cache again;
+	// since nothing changed it should not be reported as an update.
+	updated, err = stale.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v, want fallback to cache", err)
+	}
+	if updated {
+		t.Errorf("Refresh() updated = true reloading the same cache, want false")
+	}
+}